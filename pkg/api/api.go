@@ -0,0 +1,54 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+// Api represents a Dynatrace configuration API, identified by its id and
+// the REST path used to manage objects of that type.
+type Api interface {
+	GetId() string
+	GetApiPath() string
+}
+
+type apiImpl struct {
+	id      string
+	apiPath string
+}
+
+// NewApi creates a new Api with the given id and REST path.
+func NewApi(id string, apiPath string) Api {
+	return &apiImpl{
+		id:      id,
+		apiPath: apiPath,
+	}
+}
+
+func (a *apiImpl) GetId() string {
+	return a.id
+}
+
+func (a *apiImpl) GetApiPath() string {
+	return a.apiPath
+}
+
+// DynatraceEntity represents an object that has already been deployed to
+// a Dynatrace environment, so that dependent configs can reference its
+// id and name.
+type DynatraceEntity struct {
+	Id          string
+	Name        string
+	Description string
+}
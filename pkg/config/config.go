@@ -0,0 +1,393 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util/dyn"
+)
+
+// skipConfigDeploymentParameter is the legacy, all-or-nothing property name
+// used to mark a config (or one of its group/environment overrides) as not
+// to be deployed. Kept for backwards compatibility - new configs should
+// use skipActionsParameter instead.
+const skipConfigDeploymentParameter = "skipDeployment"
+
+// skipActionsParameter scopes skipping to specific lifecycle actions, e.g.
+// `skipActions: [deploy, validate]` or a bare `skipActions: deploy`.
+const skipActionsParameter = "skipActions"
+
+// ActionDeploy is the lifecycle action IsSkipDeployment/the deployer guard
+// against; kept as its own constant since it is also what the legacy
+// skipConfigDeploymentParameter implies when set.
+const ActionDeploy = "deploy"
+
+// nameParameter is the property holding the human readable name of the
+// object that gets created on the Dynatrace environment.
+const nameParameter = "name"
+
+var meIdPattern = regexp.MustCompile(`^(HOST_GROUP|APPLICATION|SERVICE)-[0-9A-F]{16}$`)
+
+// Config represents a single configuration that can be deployed to one or
+// more Dynatrace environments.
+type Config interface {
+	GetId() string
+	GetProject() string
+	GetApi() api.Api
+	GetFileName() string
+	GetConfigForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error)
+	GetObjectNameForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error)
+	GetMeIdsOfEnvironment(environment environment.Environment) map[string]map[string]string
+	IsSkipDeployment(environment environment.Environment) bool
+	ShouldSkipAction(environment environment.Environment, action string) bool
+	HasDependencyOn(config Config) bool
+}
+
+type configImpl struct {
+	id         string
+	project    string
+	template   util.Template
+	properties map[string]map[string]string
+	api        api.Api
+	fileName   string
+}
+
+// newConfig creates a new Config from already parsed properties and
+// template, layering the `<name>.override.json`, `<name>_override.json`
+// and other `*_override.json` sibling files found next to fileName on top
+// of properties first (see DiscoverOverrideFiles and LoadWithOverrides),
+// before the usual group/environment overrides ever
+// come into play. It returns any warnings collected while applying those
+// override files (e.g. a key that doesn't exist in the base config).
+//
+// fileName may be empty for configs that have no file on disk to begin
+// with (e.g. ones assembled purely in memory) - override discovery is
+// skipped in that case rather than scanning the process's working
+// directory.
+func newConfig(id string, project string, template util.Template, properties map[string]map[string]string, api api.Api, fileName string) (Config, []string, error) {
+	merged := properties
+	var warnings []string
+
+	if fileName != "" {
+		var err error
+		merged, warnings, err = LoadWithOverrides(fileName, properties)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &configImpl{
+		id:         id,
+		project:    project,
+		template:   template,
+		properties: merged,
+		api:        api,
+		fileName:   fileName,
+	}, warnings, nil
+}
+
+func (c *configImpl) GetId() string {
+	return c.id
+}
+
+func (c *configImpl) GetProject() string {
+	return c.project
+}
+
+func (c *configImpl) GetApi() api.Api {
+	return c.api
+}
+
+func (c *configImpl) GetFileName() string {
+	return c.fileName
+}
+
+// filterProperties returns the subset of properties whose key equals the
+// given prefix, preserving the location of the mapping it was filtered
+// from.
+func filterProperties(prefix string, properties dyn.Value) dyn.Value {
+	value := properties.Get(prefix)
+	if !value.IsValid() {
+		return dyn.NewMapping(nil, map[string]dyn.Value{}, properties.Location())
+	}
+	return dyn.NewMapping([]string{prefix}, map[string]dyn.Value{prefix: value}, properties.Location())
+}
+
+func (c *configImpl) GetConfigForEnvironment(env environment.Environment, dict map[string]api.DynatraceEntity) (string, error) {
+	tree := c.resolveTree(env)
+
+	data := make(map[string]interface{})
+	for _, key := range tree.Keys() {
+		data[key] = c.resolveDependencyValue(tree.Get(key), dict).Native()
+	}
+
+	mode, _ := tree.Get(templateModeParameter).AsString()
+	switch mode {
+	case templateModeRaw:
+		return substituteDependencyReferences(c.template.Content(), dict), nil
+	case templateModeMixed:
+		content := substituteDependencyReferences(c.template.Content(), dict)
+		return substituteMixedPlaceholders(content, data), nil
+	}
+
+	data["Env"] = getEnvVarMap()
+
+	return c.template.ExecuteTemplate(data)
+}
+
+func (c *configImpl) GetObjectNameForEnvironment(env environment.Environment, dict map[string]api.DynatraceEntity) (string, error) {
+	name, err := c.resolveTree(env).GetOrError(nameParameter)
+	if err != nil {
+		return "", fmt.Errorf("%w, please make sure `name` is defined for config %s/%s/%s", err, c.project, c.api.GetId(), c.id)
+	}
+
+	value, ok := name.AsString()
+	if !ok {
+		return "", fmt.Errorf("could not find name property in config %s/%s/%s, please make sure `name` is defined", c.project, c.api.GetId(), c.id)
+	}
+	return value, nil
+}
+
+// IsSkipDeployment is a thin wrapper over ShouldSkipAction kept for
+// backwards compatibility with callers that only care about deployment.
+func (c *configImpl) IsSkipDeployment(env environment.Environment) bool {
+	return c.ShouldSkipAction(env, ActionDeploy)
+}
+
+// ShouldSkipAction reports whether action should be skipped for this
+// config on env, resolved with the same group/environment precedence as
+// every other property. It understands both the legacy all-or-nothing
+// skipConfigDeploymentParameter (which only ever implies skipping
+// ActionDeploy) and the newer, scoped skipActionsParameter.
+func (c *configImpl) ShouldSkipAction(env environment.Environment, action string) bool {
+	tree := c.resolveTree(env)
+
+	if action == ActionDeploy && isSkipDeploymentSet(tree.Get(skipConfigDeploymentParameter)) {
+		return true
+	}
+
+	for _, name := range skipActionNames(tree.Get(skipActionsParameter)) {
+		if name == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSkipDeploymentSet(value dyn.Value) bool {
+	if b, ok := value.AsBool(); ok {
+		return b
+	}
+	// tolerate a plain, untyped "true" string for configs that were
+	// constructed directly against the legacy map representation
+	s, ok := value.AsString()
+	return ok && s == "true"
+}
+
+// skipActionNames interprets skipActionsParameter's resolved value as the
+// action names it names to skip. This predates (and is independent of)
+// the opt-in `list:` declared property type: a declared list value is
+// read directly as a dyn.KindSequence, but skipActionsParameter has
+// always also accepted its own bare `[a, b]`-bracketed string or a single
+// bare action name, without requiring that opt-in prefix.
+func skipActionNames(value dyn.Value) []string {
+	if seq, ok := value.AsSequence(); ok {
+		names := make([]string, 0, len(seq))
+		for _, entry := range seq {
+			if name, ok := entry.AsString(); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	str, ok := value.AsString()
+	if !ok {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(str)
+	if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+		inner := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+		if inner == "" {
+			return nil
+		}
+		parts := strings.Split(inner, ",")
+		names := make([]string, 0, len(parts))
+		for _, part := range parts {
+			names = append(names, strings.TrimSpace(part))
+		}
+		return names
+	}
+
+	return []string{trimmed}
+}
+
+// HasDependencyOn checks whether this config references the given config
+// via one of its properties.
+func (c *configImpl) HasDependencyOn(other Config) bool {
+	prefix := other.GetProject() + "/" + other.GetApi().GetId() + "/" + other.GetId()
+	for _, properties := range c.properties {
+		for _, value := range properties {
+			if strings.HasPrefix(value, prefix+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isMeId checks if the given value looks like a Dynatrace monitored entity
+// id (e.g. `APPLICATION-95BEC188F318D09C`).
+func isMeId(value string) bool {
+	return meIdPattern.MatchString(value)
+}
+
+// GetMeIdsOfEnvironment returns all properties of this config, restricted
+// to the given environment's own sections (those ending in
+// `.<environmentId>`), whose values look like monitored entity ids. Group
+// sections are not searched.
+func (c *configImpl) GetMeIdsOfEnvironment(env environment.Environment) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+
+	for section, properties := range c.properties {
+		if !strings.HasSuffix(section, "."+env.GetId()) {
+			continue
+		}
+
+		for key, value := range properties {
+			if isMeId(value) {
+				if result[section] == nil {
+					result[section] = make(map[string]string)
+				}
+				result[section][key] = value
+			}
+		}
+	}
+
+	return result
+}
+
+// parseDependency resolves a `path.field` style dependency reference
+// (where field is `id` or `name`) against the given dictionary of already
+// deployed entities.
+func (c *configImpl) parseDependency(value string, dict map[string]api.DynatraceEntity) (string, error) {
+	value = strings.TrimPrefix(value, string(os.PathSeparator))
+
+	lastDot := strings.LastIndex(value, ".")
+	if lastDot == -1 {
+		return "", fmt.Errorf("invalid dependency reference: %s", value)
+	}
+
+	path := value[:lastDot]
+	field := value[lastDot+1:]
+
+	entity, ok := dict[path]
+	if !ok {
+		return "", fmt.Errorf("could not resolve dependency %s, referenced object was not found", path)
+	}
+
+	switch field {
+	case "id":
+		return entity.Id, nil
+	case "name":
+		return entity.Name, nil
+	default:
+		return "", fmt.Errorf("invalid dependency field %s, only `id` and `name` are supported", field)
+	}
+}
+
+// replaceDependencies walks every property of the given tree and, for any
+// string value that references `otherId.id` or `otherId.name`, replaces
+// it with the actual id/name of the referenced, already deployed entity.
+// Non-string values and references to unknown entities are passed through
+// unchanged, each keeping its original location.
+func (c *configImpl) replaceDependencies(properties dyn.Value, dict map[string]api.DynatraceEntity) (dyn.Value, error) {
+	sectionOrder := properties.Keys()
+	sections := make(map[string]dyn.Value, len(sectionOrder))
+
+	for _, section := range sectionOrder {
+		sectionValue := properties.Get(section)
+		keyOrder := sectionValue.Keys()
+		resolved := make(map[string]dyn.Value, len(keyOrder))
+
+		for _, key := range keyOrder {
+			value := sectionValue.Get(key)
+			resolved[key] = c.resolveDependencyValue(value, dict)
+		}
+
+		sections[section] = dyn.NewMapping(keyOrder, resolved, sectionValue.Location())
+	}
+
+	return dyn.NewMapping(sectionOrder, sections, properties.Location()), nil
+}
+
+// resolveDependencyValue resolves a single `otherId.id`/`otherId.name`
+// string value against dict, returning value unchanged if it is not a
+// string, has no dependency reference, or references an unknown entity.
+func (c *configImpl) resolveDependencyValue(value dyn.Value, dict map[string]api.DynatraceEntity) dyn.Value {
+	str, ok := value.AsString()
+	if !ok {
+		return value
+	}
+
+	lastDot := strings.LastIndex(str, ".")
+	if lastDot == -1 {
+		return value
+	}
+
+	id := str[:lastDot]
+	field := str[lastDot+1:]
+
+	entity, ok := dict[id]
+	if !ok {
+		return value
+	}
+
+	switch field {
+	case "id":
+		return dyn.V(entity.Id, value.Location())
+	case "name":
+		return dyn.V(entity.Name, value.Location())
+	default:
+		return value
+	}
+}
+
+// envVarMap implements the `.Env.FOO` lookup used by config templates.
+// Templates are parsed with `missingkey=error`, so referencing a variable
+// that is not set in the process environment fails the template execution
+// rather than silently rendering an empty string.
+type envVarMap map[string]string
+
+func getEnvVarMap() envVarMap {
+	vars := make(envVarMap)
+	for _, kv := range os.Environ() {
+		if i := strings.Index(kv, "="); i >= 0 {
+			vars[kv[:i]] = kv[i+1:]
+		}
+	}
+	return vars
+}
@@ -60,10 +60,11 @@ func TestFilterProperties(t *testing.T) {
 
 	m["Commanders"]["Bonaparte"] = "Napoleon"
 
-	properties := filterProperties("Captains", m)
+	tree := buildPropertiesTree("", m)
+	properties := filterProperties("Captains", tree)
 
-	assert.Check(t, len(properties) == 1)
-	assert.Check(t, properties["Captains"] != nil)
+	assert.Check(t, len(properties.Keys()) == 1)
+	assert.Check(t, properties.Get("Captains").IsValid())
 }
 
 func TestGetConfigStringWithEnvironmentOverride(t *testing.T) {
@@ -71,7 +72,8 @@ func TestGetConfigStringWithEnvironmentOverride(t *testing.T) {
 	m := getTestProperties()
 	templ := getTestTemplate(t)
 
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 
 	devResult, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
 	assert.NilError(t, err)
@@ -82,7 +84,8 @@ func TestGetConfigStringNoEnvironmentOverride(t *testing.T) {
 
 	m := getTestProperties()
 	templ := getTestTemplate(t)
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 
 	hardeningResult, err := config.GetConfigForEnvironment(testHardeningEnvironment, make(map[string]api.DynatraceEntity))
 	assert.NilError(t, err)
@@ -93,7 +96,8 @@ func TestGetConfigString(t *testing.T) {
 
 	m := getTestProperties()
 	templ := getTestTemplate(t)
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 
 	devResult, devErr := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
 	hardeningResult, hardeningErr := config.GetConfigForEnvironment(testHardeningEnvironment, make(map[string]api.DynatraceEntity))
@@ -110,7 +114,8 @@ func TestGetConfigWithGroupOverride(t *testing.T) {
 
 	m := getTestProperties()
 	templ := getTestTemplate(t)
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 
 	productionResult, err := config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
 	assert.NilError(t, err)
@@ -124,7 +129,8 @@ func TestGetConfigWithGroupAndEnvironmentOverride(t *testing.T) {
 
 	m := getTestPropertiesWithGroupAndEnvironment()
 	templ := getTestTemplate(t)
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 
 	productionResult, err := config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
 	assert.NilError(t, err)
@@ -137,7 +143,8 @@ func TestGetConfigWithMergingGroupAndEnvironmentOverrides(t *testing.T) {
 
 	m := getTestPropertiesWithGroupAndEnvironment()
 	templ := getTestTemplate(t)
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 
 	// remove color parameter from `test.prod-environment`
 	// `test.production.color` parameter should be taken instead
@@ -158,36 +165,91 @@ func TestSkipConfigDeployment(t *testing.T) {
 
 	m := getTestPropertiesWithGroupAndEnvironment()
 	templ := getTestTemplate(t)
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 
 	skipDeployment := config.IsSkipDeployment(testProductionEnvironment)
 	assert.Equal(t, true, skipDeployment)
 
 	delete(m["test.prod-environment"], skipConfigDeploymentParameter)
 	m["test.production"][skipConfigDeploymentParameter] = "true"
-	config = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 	skipDeployment = config.IsSkipDeployment(testProductionEnvironment)
 	assert.Equal(t, true, skipDeployment)
 
 	delete(m["test.production"], skipConfigDeploymentParameter)
 	m["test"][skipConfigDeploymentParameter] = "true"
-	config = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 	skipDeployment = config.IsSkipDeployment(testProductionEnvironment)
 	assert.Equal(t, true, skipDeployment)
 
 	delete(m["test"], skipConfigDeploymentParameter)
-	config = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
 	skipDeployment = config.IsSkipDeployment(testProductionEnvironment)
 	assert.Equal(t, false, skipDeployment)
 }
 
+// Mirrors TestSkipConfigDeployment, but exercises the scoped skipActions
+// property at each of the same three levels, plus a multi-action list.
+func TestShouldSkipActionScopedToActionAndLevel(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	templ := getTestTemplate(t)
+
+	// isolate skipActions from the legacy skipConfigDeploymentParameter,
+	// which getTestPropertiesWithGroupAndEnvironment also sets
+	delete(m["test.prod-environment"], skipConfigDeploymentParameter)
+
+	m["test.prod-environment"][skipActionsParameter] = "[deploy, validate]"
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+	assert.Equal(t, true, config.ShouldSkipAction(testProductionEnvironment, "deploy"))
+	assert.Equal(t, true, config.ShouldSkipAction(testProductionEnvironment, "validate"))
+	assert.Equal(t, false, config.ShouldSkipAction(testProductionEnvironment, "apply"))
+
+	delete(m["test.prod-environment"], skipActionsParameter)
+	m["test.production"][skipActionsParameter] = "apply"
+	config, _, err = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+	assert.Equal(t, true, config.ShouldSkipAction(testProductionEnvironment, "apply"))
+	assert.Equal(t, false, config.ShouldSkipAction(testProductionEnvironment, "deploy"))
+
+	delete(m["test.production"], skipActionsParameter)
+	m["test"][skipActionsParameter] = "[deploy]"
+	config, _, err = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+	assert.Equal(t, true, config.ShouldSkipAction(testProductionEnvironment, "deploy"))
+
+	delete(m["test"], skipActionsParameter)
+	config, _, err = newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+	assert.Equal(t, false, config.ShouldSkipAction(testProductionEnvironment, "deploy"))
+}
+
+// The legacy skipConfigDeploymentParameter is still honoured and only ever
+// implies skipping the deploy action, never others.
+func TestShouldSkipActionFallsBackToLegacySkipDeployment(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	templ := getTestTemplate(t)
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	assert.Equal(t, true, config.ShouldSkipAction(testProductionEnvironment, ActionDeploy))
+	assert.Equal(t, false, config.ShouldSkipAction(testProductionEnvironment, "validate"))
+}
+
 // Test getting object name for environment
 // considering environment and group overrides
 func TestGetObjectNameForEnvironment(t *testing.T) {
 
 	m := getTestPropertiesWithGroupAndEnvironment()
 	templ := getTestTemplate(t)
-	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "projects/testproject/management-zone/test.json")
+	assert.NilError(t, err)
 
 	productionResult, err := config.GetObjectNameForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
 	assert.NilError(t, err)
@@ -212,10 +274,31 @@ func TestGetObjectNameForEnvironment(t *testing.T) {
 	delete(m["test"], "name")
 	productionResult, err = config.GetObjectNameForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
 
-	expected := util.ReplacePathSeparators("could not find name property in config testproject/management-zone/test, please make sure `name` is defined")
+	// Line/column are always 1:1 here since no YAML/JSON loader in this
+	// tree yet builds the tree directly from a parsed file - only the
+	// file name comes from a real source. See buildPropertiesTree.
+	expected := "could not find `name` at projects/testproject/management-zone/test.json:1:1, please make sure `name` is defined for config testproject/management-zone/test"
 	assert.Error(t, err, expected)
 }
 
+// A plain, untyped `name` that merely looks like a number must still be
+// read back as its literal string - it must never be resolved into the
+// `int:`-declared type just because it has the right shape.
+func TestGetObjectNameForEnvironmentWithNumericLookingName(t *testing.T) {
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"]["name"] = "2024"
+
+	templ := getTestTemplate(t)
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	result, err := config.GetObjectNameForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "2024", result)
+}
+
 func getTestTemplate(t *testing.T) util.Template {
 	template, e := util.NewTemplateFromString("test", testTemplate)
 	assert.NilError(t, e)
@@ -279,19 +362,25 @@ func TestReplaceDependency(t *testing.T) {
 	dict["Foo"] = entity1
 	dict["Bar"] = entity2
 
-	data := make(map[string]map[string]string)
-	data["obj"] = make(map[string]string)
+	m := make(map[string]map[string]string)
+	m["obj"] = make(map[string]string)
+
+	m["obj"]["k1"] = "value"
+	m["obj"]["k2"] = "Bar.id"
+	m["obj"]["k3"] = "Foo.name"
 
-	data["obj"]["k1"] = "value"
-	data["obj"]["k2"] = "Bar.id"
-	data["obj"]["k3"] = "Foo.name"
+	tree := buildPropertiesTree("", m)
 
 	config := configImpl{}
-	data, err := config.replaceDependencies(data, dict)
+	resolved, err := config.replaceDependencies(tree, dict)
 	assert.NilError(t, err)
-	assert.Equal(t, "value", data["obj"]["k1"])
-	assert.Equal(t, "asdf", data["obj"]["k2"])
-	assert.Equal(t, "MyCustomObj", data["obj"]["k3"])
+
+	k1, _ := resolved.Get("obj").Get("k1").AsString()
+	k2, _ := resolved.Get("obj").Get("k2").AsString()
+	k3, _ := resolved.Get("obj").Get("k3").AsString()
+	assert.Equal(t, "value", k1)
+	assert.Equal(t, "asdf", k2)
+	assert.Equal(t, "MyCustomObj", k3)
 }
 
 func TestHasDependencyCheck(t *testing.T) {
@@ -302,9 +391,11 @@ func TestHasDependencyCheck(t *testing.T) {
 	temp, e := util.NewTemplateFromString("test", "{{.name}}{{.somethingelse}}")
 	assert.NilError(t, e)
 
-	config := newConfig("test", "testproject", temp, prop, testManagementZoneApi, "test.json")
+	config, _, err := newConfig("test", "testproject", temp, prop, testManagementZoneApi, "test.json")
+	assert.NilError(t, err)
 
-	otherConfig := newConfig("other", "testproject", temp, make(map[string]map[string]string), testManagementZoneApi, "other.json")
+	otherConfig, _, err := newConfig("other", "testproject", temp, make(map[string]map[string]string), testManagementZoneApi, "other.json")
+	assert.NilError(t, err)
 
 	assert.Equal(t, true, config.HasDependencyOn(otherConfig))
 }
@@ -394,7 +485,8 @@ func TestGetConfigStringWithEnvVar(t *testing.T) {
 
 	util.SetEnv(t, "ANIMAL", "cow")
 
-	config := newConfig("test", "testproject", templ, getTestProperties(), testManagementZoneApi, "")
+	config, _, err := newConfig("test", "testproject", templ, getTestProperties(), testManagementZoneApi, "")
+	assert.NilError(t, err)
 	devResult, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
 
 	util.UnsetEnv(t, "ANIMAL")
@@ -409,8 +501,165 @@ func TestGetConfigStringWithEnvVarLeadsToErrorIfEnvVarNotPresent(t *testing.T) {
 
 	util.UnsetEnv(t, "ANIMAL")
 
-	config := newConfig("test", "testproject", templ, getTestProperties(), testManagementZoneApi, "")
-	_, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	config, _, err := newConfig("test", "testproject", templ, getTestProperties(), testManagementZoneApi, "")
+	assert.NilError(t, err)
+	_, err = config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
 
 	assert.ErrorContains(t, err, "map has no entry for key \"ANIMAL\"")
 }
+
+// Typed property overrides should reach the template as their declared
+// type, not as quoted strings, so that `{{ if }}`, `{{ range }}` and
+// arithmetic work naturally.
+func TestGetConfigWithTypedProperties(t *testing.T) {
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"]["enabled"] = "bool:true"
+	m["test"]["workers"] = "int:3"
+	m["test"]["tags"] = "list:[a, b, c]"
+
+	templ, e := util.NewTemplateFromString("test", "{{ if .enabled }}enabled{{ end }} workers={{.workers}} tags={{ range .tags }}{{.}}{{ end }}")
+	assert.NilError(t, e)
+
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	result, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "enabled workers=3 tags=abc", result)
+}
+
+// A typed list override at a more specific level replaces, rather than
+// merges into, the list at a less specific level.
+func TestGetConfigWithTypedListOverrideReplacesBaseList(t *testing.T) {
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"]["tags"] = "list:[a, b]"
+
+	m["test.production"] = make(map[string]string)
+	m["test.production"]["tags"] = "list:[c]"
+
+	templ, e := util.NewTemplateFromString("test", "{{ range .tags }}{{.}}{{ end }}")
+	assert.NilError(t, e)
+
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	result, err := config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "c", result)
+}
+
+// Mirrors TestGetConfigString, but for templateMode: raw - the template
+// body is returned verbatim, even though it references a field that is
+// not defined anywhere and would otherwise fail execution.
+func TestGetConfigStringWithRawTemplateMode(t *testing.T) {
+
+	rawBody := `{"name": "{{ .someDynatraceServerSideExpression }}"}`
+	templ, e := util.NewTemplateFromString("test", rawBody)
+	assert.NilError(t, e)
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"][templateModeParameter] = templateModeRaw
+
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	result, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, rawBody, result)
+}
+
+// Mirrors TestReplaceDependency, but dependency references are resolved
+// directly in the raw template body instead of in a property value.
+func TestGetConfigStringWithRawTemplateModeResolvesDependencies(t *testing.T) {
+
+	rawBody := `{"managementZoneId": "Bar.id"}`
+	templ, e := util.NewTemplateFromString("test", rawBody)
+	assert.NilError(t, e)
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"][templateModeParameter] = templateModeRaw
+
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	dict := make(map[string]api.DynatraceEntity)
+	dict["Bar"] = api.DynatraceEntity{Id: "asdf", Name: "MySuperObj"}
+
+	result, err := config.GetConfigForEnvironment(testDevEnvironment, dict)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"managementZoneId": "asdf"}`, result)
+}
+
+// A dependency id that is a suffix of an unrelated identifier must not be
+// substituted as a substring match - only whole "id.field" references.
+func TestGetConfigStringWithRawTemplateModeDoesNotSubstituteSuffixMatches(t *testing.T) {
+
+	rawBody := `{"a": "FooBar.id", "b": "Bar.id"}`
+	templ, e := util.NewTemplateFromString("test", rawBody)
+	assert.NilError(t, e)
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"][templateModeParameter] = templateModeRaw
+
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	dict := make(map[string]api.DynatraceEntity)
+	dict["Bar"] = api.DynatraceEntity{Id: "REPLACED", Name: "MySuperObj"}
+
+	result, err := config.GetConfigForEnvironment(testDevEnvironment, dict)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"a": "FooBar.id", "b": "REPLACED"}`, result)
+}
+
+// Mirrors TestGetConfigString, but for templateMode: mixed - only the
+// explicit ${...} placeholders are substituted, everything else (in
+// particular the JSON braces) is left untouched.
+func TestGetConfigStringWithMixedTemplateMode(t *testing.T) {
+
+	rawBody := `{"color": "${color}", "literal": "{{ .color }}"}`
+	templ, e := util.NewTemplateFromString("test", rawBody)
+	assert.NilError(t, e)
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"][templateModeParameter] = templateModeMixed
+	m["test"]["color"] = "white"
+
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	result, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, `{"color": "white", "literal": "{{ .color }}"}`, result)
+}
+
+// A typed (non-string) property substituted via ${...} in mixed mode
+// should render as its natural value, not be left as an unsubstituted
+// placeholder.
+func TestGetConfigStringWithMixedTemplateModeSubstitutesTypedProperties(t *testing.T) {
+
+	rawBody := `{"workers": ${workers}, "enabled": ${enabled}}`
+	templ, e := util.NewTemplateFromString("test", rawBody)
+	assert.NilError(t, e)
+
+	m := make(map[string]map[string]string)
+	m["test"] = make(map[string]string)
+	m["test"][templateModeParameter] = templateModeMixed
+	m["test"]["workers"] = "int:3"
+	m["test"]["enabled"] = "bool:true"
+
+	config, _, err := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+	assert.NilError(t, err)
+
+	result, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, `{"workers": 3, "enabled": true}`, result)
+}
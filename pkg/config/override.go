@@ -0,0 +1,173 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// replaceDirective is set on a section to indicate that an override should
+// replace the base section wholesale instead of being merged key by key -
+// the config equivalent of Terraform's `*_override.tf` "last one wins"
+// blocks for nested structures.
+const replaceDirective = "__replace"
+
+// DiscoverOverrideFiles returns the override files that apply to the base
+// config file at basePath, in the precedence order they should be applied
+// in (lexical order, lowest precedence first).
+//
+// For a base file `foo.json`, the following siblings are picked up:
+//   - foo.override.json
+//   - foo_override.json
+//   - any *_override.json file in the same directory
+func DiscoverOverrideFiles(basePath string) ([]string, error) {
+	dir := filepath.Dir(basePath)
+	base := filepath.Base(basePath)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	candidates := map[string]bool{
+		stem + ".override" + ext: true,
+		stem + "_override" + ext: true,
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if candidates[name] || (strings.HasSuffix(name, "_override"+ext) && name != base) {
+			matches = append(matches, filepath.Join(dir, name))
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// parseOverrideFile reads a `{"section": {"key": "value"}}` style JSON
+// override file into the same map[string]map[string]string shape configs
+// are otherwise loaded into.
+func parseOverrideFile(path string) (map[string]map[string]string, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := make(map[string]map[string]string)
+	if err := json.Unmarshal(raw, &properties); err != nil {
+		return nil, err
+	}
+	return properties, nil
+}
+
+// ApplyOverrides deep-merges override on top of base, section by section,
+// key by key, returning the merged result plus warnings about keys the
+// override set in a section the base already has, but which that base
+// section lacks (most likely typos). A section the base does not have at
+// all is added as is, without warnings.
+//
+// A section in override that carries the `__replace` directive set to
+// "true" replaces the corresponding base section wholesale rather than
+// being merged into it, also without warnings.
+func ApplyOverrides(base map[string]map[string]string, override map[string]map[string]string) (map[string]map[string]string, []string) {
+	merged := make(map[string]map[string]string, len(base))
+	for section, properties := range base {
+		merged[section] = copyProperties(properties)
+	}
+
+	var warnings []string
+
+	for section, overrideProperties := range override {
+		replace := overrideProperties[replaceDirective] == "true"
+
+		if replace {
+			replaced := copyProperties(overrideProperties)
+			delete(replaced, replaceDirective)
+			merged[section] = replaced
+			continue
+		}
+
+		baseProperties, sectionExists := merged[section]
+		if !sectionExists {
+			added := copyProperties(overrideProperties)
+			delete(added, replaceDirective)
+			merged[section] = added
+			continue
+		}
+
+		for key, value := range overrideProperties {
+			if key == replaceDirective {
+				continue
+			}
+			if _, ok := baseProperties[key]; !ok {
+				warnings = append(warnings, "override references key `"+key+"` in section `"+section+"` which does not exist in the base config")
+			}
+			baseProperties[key] = value
+		}
+	}
+
+	return merged, warnings
+}
+
+// LoadWithOverrides applies every override file discovered for basePath on
+// top of baseProperties, in precedence order, and returns the merged
+// properties plus any warnings collected along the way.
+func LoadWithOverrides(basePath string, baseProperties map[string]map[string]string) (map[string]map[string]string, []string, error) {
+	overrideFiles, err := DiscoverOverrideFiles(basePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged := baseProperties
+	var warnings []string
+
+	for _, overrideFile := range overrideFiles {
+		overrideProperties, err := parseOverrideFile(overrideFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var fileWarnings []string
+		merged, fileWarnings = ApplyOverrides(merged, overrideProperties)
+		warnings = append(warnings, fileWarnings...)
+	}
+
+	return merged, warnings, nil
+}
+
+func copyProperties(properties map[string]string) map[string]string {
+	copied := make(map[string]string, len(properties))
+	for key, value := range properties {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,131 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	assert.NilError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestDiscoverOverrideFilesFindsAllNamingConventions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monaco-override-test")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	basePath := writeTestFile(t, dir, "foo.json", `{}`)
+	writeTestFile(t, dir, "foo.override.json", `{}`)
+	writeTestFile(t, dir, "foo_override.json", `{}`)
+	writeTestFile(t, dir, "prod_override.json", `{}`)
+	writeTestFile(t, dir, "unrelated.json", `{}`)
+
+	found, err := DiscoverOverrideFiles(basePath)
+	assert.NilError(t, err)
+	assert.Equal(t, 3, len(found))
+}
+
+func TestApplyOverridesMergesKeyByKeyAndWarnsOnTypos(t *testing.T) {
+	base := map[string]map[string]string{
+		"test": {"color": "white", "animalType": "rabbit"},
+	}
+	override := map[string]map[string]string{
+		"test": {"color": "brown", "aminalType": "dog"},
+	}
+
+	merged, warnings := ApplyOverrides(base, override)
+
+	assert.Equal(t, "brown", merged["test"]["color"])
+	assert.Equal(t, "rabbit", merged["test"]["animalType"])
+	assert.Equal(t, "dog", merged["test"]["aminalType"])
+	assert.Equal(t, 1, len(warnings))
+}
+
+// An explicit `__replace: false` (or any value other than "true") means
+// "merge as usual", and the directive key itself must never leak into the
+// merged properties.
+func TestApplyOverridesReplaceDirectiveFalseStillMergesAndIsStripped(t *testing.T) {
+	base := map[string]map[string]string{
+		"test": {"color": "white", "animalType": "rabbit"},
+	}
+	override := map[string]map[string]string{
+		"test": {"__replace": "false", "color": "red"},
+	}
+
+	merged, warnings := ApplyOverrides(base, override)
+
+	assert.Equal(t, "red", merged["test"]["color"])
+	assert.Equal(t, "rabbit", merged["test"]["animalType"])
+	_, hasDirective := merged["test"]["__replace"]
+	assert.Check(t, !hasDirective)
+	assert.Equal(t, 0, len(warnings))
+}
+
+func TestApplyOverridesReplaceDirectiveReplacesWholeSection(t *testing.T) {
+	base := map[string]map[string]string{
+		"test": {"color": "white", "animalType": "rabbit"},
+	}
+	override := map[string]map[string]string{
+		"test": {"__replace": "true", "color": "red"},
+	}
+
+	merged, warnings := ApplyOverrides(base, override)
+
+	assert.Equal(t, "red", merged["test"]["color"])
+	_, hasAnimalType := merged["test"]["animalType"]
+	assert.Check(t, !hasAnimalType)
+	assert.Equal(t, 0, len(warnings))
+}
+
+// Mirrors TestGetConfigWithMergingGroupAndEnvironmentOverrides, but with the
+// base config and its override layered from files on disk.
+func TestGetConfigWithOverrideFilesOnDisk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monaco-override-test")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	basePath := writeTestFile(t, dir, "test.json", `{
+		"test": {"color": "white", "animalType": "rabbit"},
+		"test.production": {"color": "brown", "animalType": "dog"}
+	}`)
+	writeTestFile(t, dir, "test.override.json", `{
+		"test.production": {"color": "red"}
+	}`)
+
+	templ := getTestTemplate(t)
+	baseProperties, err := parseOverrideFile(basePath)
+	assert.NilError(t, err)
+
+	cfg, warnings, err := newConfig("test", "testproject", templ, baseProperties, testManagementZoneApi, basePath)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(warnings))
+
+	result, err := cfg.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "Follow the red dog", result)
+}
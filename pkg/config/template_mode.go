@@ -0,0 +1,96 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// templateModeParameter selects how a config's template is rendered. It
+// defaults to "" (standard Go templating, the only mode that existed
+// before raw/mixed were introduced).
+const templateModeParameter = "templateMode"
+
+const (
+	// templateModeRaw returns the template file verbatim, only resolving
+	// dependency references - no {{ }} evaluation, no .Env.* lookup. Use
+	// this for payloads that legitimately contain Go-template-looking
+	// braces or Dynatrace server-side expressions.
+	templateModeRaw = "raw"
+	// templateModeMixed is like raw, but additionally substitutes a small
+	// allowlisted placeholder syntax, `${property.name}`, so that a JSON
+	// payload full of literal curly braces can still reference config
+	// properties without being run through text/template.
+	templateModeMixed = "mixed"
+)
+
+// mixedPlaceholderPattern matches the `${property.name}` placeholders
+// understood by templateModeMixed.
+var mixedPlaceholderPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// substituteDependencyReferences replaces every `<dependencyId>.id` and
+// `<dependencyId>.name` occurrence in content with the actual id/name of
+// the already deployed entity, using the same dependency syntax
+// resolveDependencyValue resolves in the property values of templated
+// configs.
+//
+// Unlike a plain strings.ReplaceAll, each reference must be a whole,
+// standalone token - not preceded by a letter, digit or underscore - so a
+// dependency id that happens to be a suffix of an unrelated identifier
+// (e.g. "Bar" inside "FooBar.id") is left untouched.
+func substituteDependencyReferences(content string, dict map[string]api.DynatraceEntity) string {
+	for id, entity := range dict {
+		content = replaceDependencyReference(content, id, "id", entity.Id)
+		content = replaceDependencyReference(content, id, "name", entity.Name)
+	}
+	return content
+}
+
+// replaceDependencyReference replaces every standalone `id.field`
+// occurrence in content with value.
+func replaceDependencyReference(content string, id string, field string, value string) string {
+	suffix := "." + field
+	pattern := regexp.MustCompile(`(^|[^A-Za-z0-9_])` + regexp.QuoteMeta(id+suffix) + `\b`)
+
+	return pattern.ReplaceAllStringFunc(content, func(match string) string {
+		if len(match) > len(id)+len(suffix) {
+			return match[:1] + value
+		}
+		return value
+	})
+}
+
+// substituteMixedPlaceholders replaces every `${property.name}` in content
+// with the string representation of that property, leaving placeholders
+// referencing unknown properties untouched.
+func substituteMixedPlaceholders(content string, properties map[string]interface{}) string {
+	return mixedPlaceholderPattern.ReplaceAllStringFunc(content, func(match string) string {
+		key := strings.TrimSuffix(strings.TrimPrefix(match, "${"), "}")
+		value, ok := properties[key]
+		if !ok {
+			return match
+		}
+		if s, ok := value.(string); ok {
+			return s
+		}
+		return fmt.Sprint(value)
+	})
+}
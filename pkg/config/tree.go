@@ -0,0 +1,112 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"sort"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util/dyn"
+)
+
+// buildPropertiesTree converts the legacy `map[string]map[string]string`
+// representation into a dyn.Value tree, so that diagnostics produced while
+// resolving a config can point at the file they came from.
+//
+// This is a stub, not the real location tracking the dyn.Value design is
+// for: there is no structured YAML/JSON loader in this tree yet that
+// parses a config file directly into a dyn.Value and records where each
+// scalar actually sits in the source. Every Location built here is
+// therefore only file-accurate - Line and Column are hardcoded to 1:1
+// regardless of where a value really is, for every config, including ones
+// that went through the legacy map-based loading path as well as ones
+// only ever constructed in memory (e.g. in tests). Treat this as
+// incremental infrastructure only; "could not find `name` at
+// foo.yaml:1:1" is not yet evidence that a diagnostic points at the right
+// line - that still needs the real loader to be written.
+func buildPropertiesTree(fileName string, properties map[string]map[string]string) dyn.Value {
+	loc := dyn.Location{File: fileName, Line: 1, Column: 1}
+
+	sections := make(map[string]dyn.Value, len(properties))
+	sectionOrder := sortedSectionKeys(properties)
+
+	for _, section := range sectionOrder {
+		entries := properties[section]
+		entryOrder := sortedKeys(entries)
+
+		values := make(map[string]dyn.Value, len(entries))
+		for key, value := range entries {
+			values[key] = dyn.ParseDeclaredScalar(value, loc)
+		}
+
+		sections[section] = dyn.NewMapping(entryOrder, values, loc)
+	}
+
+	return dyn.NewMapping(sectionOrder, sections, loc)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSectionKeys(m map[string]map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveTree returns the dyn.Value mapping of properties that apply to
+// env, merging config-wide, group and environment specific sections with
+// environment overrides taking precedence over group overrides, which in
+// turn take precedence over the config-wide defaults. A value at a more
+// specific level fully replaces (rather than merges into) the same key at
+// a less specific level - including list-typed values.
+//
+// The tree is rebuilt from c.properties on every call rather than cached,
+// since properties may still be mutated directly by legacy callers during
+// the staged migration to the dynamic representation.
+func (c *configImpl) resolveTree(env environment.Environment) dyn.Value {
+	tree := buildPropertiesTree(c.fileName, c.properties)
+
+	merged := make(map[string]dyn.Value)
+	var order []string
+
+	merge := func(section dyn.Value) {
+		for _, key := range section.Keys() {
+			if _, exists := merged[key]; !exists {
+				order = append(order, key)
+			}
+			merged[key] = section.Get(key)
+		}
+	}
+
+	merge(tree.Get(c.id))
+	if env.GetGroup() != "" {
+		merge(tree.Get(c.id + "." + env.GetGroup()))
+	}
+	merge(tree.Get(c.id + "." + env.GetId()))
+
+	return dyn.NewMapping(order, merged, tree.Location())
+}
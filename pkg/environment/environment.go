@@ -0,0 +1,68 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment
+
+// Environment represents a single Dynatrace environment configs can be
+// deployed to, optionally assigned to a group for shared overrides.
+type Environment interface {
+	GetId() string
+	GetName() string
+	GetGroup() string
+	GetEnvironmentUrl() string
+	GetTokenEnvVar() string
+}
+
+type environmentImpl struct {
+	id             string
+	name           string
+	group          string
+	environmentUrl string
+	tokenEnvVar    string
+}
+
+// NewEnvironment creates a new Environment with the given id, name, group
+// (empty if the environment does not belong to one), url and token env
+// var name.
+func NewEnvironment(id string, name string, group string, environmentUrl string, tokenEnvVar string) Environment {
+	return &environmentImpl{
+		id:             id,
+		name:           name,
+		group:          group,
+		environmentUrl: environmentUrl,
+		tokenEnvVar:    tokenEnvVar,
+	}
+}
+
+func (e *environmentImpl) GetId() string {
+	return e.id
+}
+
+func (e *environmentImpl) GetName() string {
+	return e.name
+}
+
+func (e *environmentImpl) GetGroup() string {
+	return e.group
+}
+
+func (e *environmentImpl) GetEnvironmentUrl() string {
+	return e.environmentUrl
+}
+
+func (e *environmentImpl) GetTokenEnvVar() string {
+	return e.tokenEnvVar
+}
@@ -0,0 +1,351 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dyn provides a dynamic, location-aware representation of
+// configuration data loaded from YAML/JSON files. Every Value carries a
+// Location (file, line and column) it was parsed from, so that callers
+// can produce diagnostics that point at the exact place a problem
+// originates from, rather than only at the config id and api - once a
+// structured loader exists that parses source files directly into a
+// dyn.Value and records where each scalar really came from. Until then,
+// see the stub note on pkg/config's buildPropertiesTree: every Location
+// it produces today is only file-accurate, with Line and Column hardcoded
+// to 1:1.
+package dyn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind identifies which variant of data a Value holds.
+type Kind int
+
+const (
+	KindInvalid Kind = iota
+	KindString
+	KindInt
+	KindBool
+	KindFloat
+	KindSequence
+	KindMapping
+	KindNil
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindBool:
+		return "bool"
+	case KindFloat:
+		return "float"
+	case KindSequence:
+		return "sequence"
+	case KindMapping:
+		return "mapping"
+	case KindNil:
+		return "nil"
+	default:
+		return "invalid"
+	}
+}
+
+// Location is the source position a Value was loaded from.
+type Location struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
+}
+
+// Value is a single node in a dynamic configuration tree. A Value is
+// immutable - mutating helpers return a new Value rather than modifying
+// the receiver in place.
+type Value struct {
+	kind     Kind
+	location Location
+
+	str  string
+	i    int
+	b    bool
+	f    float64
+	seq  []Value
+	mapp map[string]Value
+	// order preserves the original key order of a mapping, since maps in Go
+	// are unordered but config file order matters for diagnostics.
+	order []string
+}
+
+// Invalid returns a Value representing the absence of any value - the
+// analogue of "key not found" that, unlike a zero value, can never be
+// confused with an explicitly set empty string or false.
+func Invalid() Value {
+	return Value{kind: KindInvalid}
+}
+
+// NilValue returns a Value representing an explicit null/nil in the
+// source document - distinct from Invalid, which means "not present".
+func NilValue(loc Location) Value {
+	return Value{kind: KindNil, location: loc}
+}
+
+// V creates a Value from a Go value, inferring its Kind. Supported types
+// are string, int, bool, float64, []Value and nil; mappings are built
+// with NewMapping instead, and any other type yields Invalid().
+func V(value interface{}, loc Location) Value {
+	switch v := value.(type) {
+	case string:
+		return Value{kind: KindString, str: v, location: loc}
+	case int:
+		return Value{kind: KindInt, i: v, location: loc}
+	case bool:
+		return Value{kind: KindBool, b: v, location: loc}
+	case float64:
+		return Value{kind: KindFloat, f: v, location: loc}
+	case []Value:
+		return Value{kind: KindSequence, seq: v, location: loc}
+	case nil:
+		return NilValue(loc)
+	default:
+		return Invalid()
+	}
+}
+
+// NewMapping creates a mapping Value, preserving the given key order.
+func NewMapping(order []string, entries map[string]Value, loc Location) Value {
+	return Value{kind: KindMapping, order: order, mapp: entries, location: loc}
+}
+
+// NewSequence creates a sequence Value from already parsed elements.
+func NewSequence(elements []Value, loc Location) Value {
+	return Value{kind: KindSequence, seq: elements, location: loc}
+}
+
+// declaredTypePrefixes are the `<type>:` tags a raw property value can
+// opt into to be parsed as something other than a plain string. Anything
+// without one of these prefixes - including a value that merely looks
+// like a number, bool or list - is always KindString.
+const (
+	declaredTypeInt   = "int:"
+	declaredTypeBool  = "bool:"
+	declaredTypeFloat = "float:"
+	declaredTypeList  = "list:"
+)
+
+// ParseDeclaredScalar parses a raw property value that may carry an
+// explicit, opt-in declared type via one of the declaredTypePrefixes
+// (e.g. `int:3`, `bool:true`, `float:1.20`, `list:[a, b, c]`). A value
+// without one of these prefixes always stays KindString, no matter what
+// it looks like - unlike shape-based inference, this never silently
+// reinterprets a legacy string property such as a `name` of "2024" or a
+// `version` of "1.20" as a number, and never turns a literal tag like
+// "[DEPRECATED]" into a list.
+//
+// This lets property overrides that want a non-string value - the only
+// thing the current YAML/JSON loaders produce is plain strings - opt
+// into one without requiring every call site to be migrated to a
+// structured loader at once.
+func ParseDeclaredScalar(raw string, loc Location) Value {
+	trimmed := strings.TrimSpace(raw)
+
+	if rest, ok := strings.CutPrefix(trimmed, declaredTypeInt); ok {
+		if i, err := strconv.Atoi(strings.TrimSpace(rest)); err == nil {
+			return Value{kind: KindInt, i: i, location: loc}
+		}
+		return Value{kind: KindString, str: raw, location: loc}
+	}
+
+	if rest, ok := strings.CutPrefix(trimmed, declaredTypeBool); ok {
+		switch strings.TrimSpace(rest) {
+		case "true":
+			return Value{kind: KindBool, b: true, location: loc}
+		case "false":
+			return Value{kind: KindBool, b: false, location: loc}
+		}
+		return Value{kind: KindString, str: raw, location: loc}
+	}
+
+	if rest, ok := strings.CutPrefix(trimmed, declaredTypeFloat); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(rest), 64); err == nil {
+			return Value{kind: KindFloat, f: f, location: loc}
+		}
+		return Value{kind: KindString, str: raw, location: loc}
+	}
+
+	if rest, ok := strings.CutPrefix(trimmed, declaredTypeList); ok {
+		return parseDeclaredList(rest, loc)
+	}
+
+	return Value{kind: KindString, str: raw, location: loc}
+}
+
+// parseDeclaredList parses the payload of a `list:` declared value. Its
+// elements are plain strings - list:[a, b, c] is never further sniffed
+// for nested types.
+func parseDeclaredList(raw string, loc Location) Value {
+	trimmed := strings.TrimSpace(raw)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+		return Value{kind: KindString, str: raw, location: loc}
+	}
+
+	inner := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+	if inner == "" {
+		return NewSequence(nil, loc)
+	}
+
+	parts := strings.Split(inner, ",")
+	elements := make([]Value, 0, len(parts))
+	for _, part := range parts {
+		elements = append(elements, Value{kind: KindString, str: strings.TrimSpace(part), location: loc})
+	}
+	return NewSequence(elements, loc)
+}
+
+// Native converts a Value back into a plain Go value (string, int, bool,
+// float64, []interface{} or map[string]interface{}), suitable for handing
+// to text/template or other code that predates the typed representation.
+func (v Value) Native() interface{} {
+	switch v.kind {
+	case KindString:
+		return v.str
+	case KindInt:
+		return v.i
+	case KindBool:
+		return v.b
+	case KindFloat:
+		return v.f
+	case KindSequence:
+		native := make([]interface{}, 0, len(v.seq))
+		for _, elem := range v.seq {
+			native = append(native, elem.Native())
+		}
+		return native
+	case KindMapping:
+		native := make(map[string]interface{}, len(v.mapp))
+		for key, elem := range v.mapp {
+			native[key] = elem.Native()
+		}
+		return native
+	default:
+		return nil
+	}
+}
+
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+func (v Value) Location() Location {
+	return v.location
+}
+
+// IsValid reports whether this Value represents actually loaded data, as
+// opposed to a missing key.
+func (v Value) IsValid() bool {
+	return v.kind != KindInvalid
+}
+
+func (v Value) AsString() (string, bool) {
+	if v.kind != KindString {
+		return "", false
+	}
+	return v.str, true
+}
+
+func (v Value) AsInt() (int, bool) {
+	if v.kind != KindInt {
+		return 0, false
+	}
+	return v.i, true
+}
+
+func (v Value) AsBool() (bool, bool) {
+	if v.kind != KindBool {
+		return false, false
+	}
+	return v.b, true
+}
+
+func (v Value) AsFloat() (float64, bool) {
+	if v.kind != KindFloat {
+		return 0, false
+	}
+	return v.f, true
+}
+
+func (v Value) AsSequence() ([]Value, bool) {
+	if v.kind != KindSequence {
+		return nil, false
+	}
+	return v.seq, true
+}
+
+func (v Value) AsMapping() (map[string]Value, bool) {
+	if v.kind != KindMapping {
+		return nil, false
+	}
+	return v.mapp, true
+}
+
+// Keys returns the keys of a mapping Value in their original source order.
+// Returns nil for any other Kind.
+func (v Value) Keys() []string {
+	if v.kind != KindMapping {
+		return nil
+	}
+	return v.order
+}
+
+// Get looks up key in a mapping Value, returning Invalid() if this Value
+// is not a mapping or does not contain key.
+func (v Value) Get(key string) Value {
+	if v.kind != KindMapping {
+		return Invalid()
+	}
+	if found, ok := v.mapp[key]; ok {
+		return found
+	}
+	return Invalid()
+}
+
+// NotFoundError describes a lookup that failed to find `key`, pointing at
+// the location of the mapping that was searched so callers can produce
+// `could not find X at file:line:col` style diagnostics.
+type NotFoundError struct {
+	Key      string
+	Location Location
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("could not find `%s` at %s", e.Key, e.Location)
+}
+
+// GetOrError is like Get, but returns a *NotFoundError pointing at the
+// mapping's own location when key is missing.
+func (v Value) GetOrError(key string) (Value, error) {
+	result := v.Get(key)
+	if !result.IsValid() {
+		return Invalid(), &NotFoundError{Key: key, Location: v.location}
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dyn
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestInvalidValueIsNotValid(t *testing.T) {
+	assert.Check(t, !Invalid().IsValid())
+}
+
+func TestZeroValuesAreDistinguishableFromUnset(t *testing.T) {
+	loc := Location{File: "test.yaml", Line: 3, Column: 5}
+
+	emptyString := V("", loc)
+	str, ok := emptyString.AsString()
+	assert.Check(t, ok)
+	assert.Equal(t, "", str)
+	assert.Check(t, emptyString.IsValid())
+
+	falseValue := V(false, loc)
+	b, ok := falseValue.AsBool()
+	assert.Check(t, ok)
+	assert.Equal(t, false, b)
+	assert.Check(t, falseValue.IsValid())
+
+	assert.Check(t, !Invalid().IsValid())
+}
+
+func TestMappingGetReturnsInvalidForMissingKey(t *testing.T) {
+	loc := Location{File: "test.yaml", Line: 1, Column: 1}
+	m := NewMapping([]string{"a"}, map[string]Value{"a": V("value", loc)}, loc)
+
+	found := m.Get("a")
+	value, ok := found.AsString()
+	assert.Check(t, ok)
+	assert.Equal(t, "value", value)
+
+	missing := m.Get("b")
+	assert.Check(t, !missing.IsValid())
+}
+
+func TestGetOrErrorReportsLocation(t *testing.T) {
+	loc := Location{File: "projects/foo/management-zone/bar.yaml", Line: 14, Column: 3}
+	m := NewMapping(nil, map[string]Value{}, loc)
+
+	_, err := m.GetOrError("name")
+	assert.Error(t, err, "could not find `name` at projects/foo/management-zone/bar.yaml:14:3")
+}
@@ -0,0 +1,40 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"os"
+	"testing"
+)
+
+// SetEnv sets an environment variable for a test, failing the test
+// immediately if the variable could not be set. The variable is not
+// restored when the test ends; it stays set until UnsetEnv removes it.
+func SetEnv(t *testing.T, key, value string) {
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatalf("failed to set env var %s: %s", key, err)
+	}
+}
+
+// UnsetEnv removes an environment variable previously set via SetEnv.
+func UnsetEnv(t *testing.T, key string) {
+	if err := os.Unsetenv(key); err != nil {
+		t.Fatalf("failed to unset env var %s: %s", key, err)
+	}
+}
@@ -0,0 +1,85 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"text/template"
+)
+
+// Template wraps a parsed go template together with the name and raw
+// content it was created from, so that callers can re-execute it against
+// different data contexts without re-reading it from disk.
+type Template interface {
+	Id() string
+	Name() string
+	Content() string
+	ExecuteTemplate(data interface{}) (string, error)
+}
+
+type templateImpl struct {
+	id       string
+	name     string
+	content  string
+	template *template.Template
+}
+
+// NewTemplateFromString creates a new Template from a string, using id
+// both as the template's id and name.
+func NewTemplateFromString(id string, content string) (Template, error) {
+	parsed, err := template.New(id).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	return &templateImpl{
+		id:       id,
+		name:     id,
+		content:  content,
+		template: parsed,
+	}, nil
+}
+
+// NewTemplateFromFile reads the file at path and creates a Template from
+// its content, using path as the template's id.
+func NewTemplateFromFile(path string) (Template, error) {
+	bytesRead, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewTemplateFromString(path, string(bytesRead))
+}
+
+func (t *templateImpl) Id() string {
+	return t.id
+}
+
+func (t *templateImpl) Name() string {
+	return t.name
+}
+
+func (t *templateImpl) Content() string {
+	return t.content
+}
+
+func (t *templateImpl) ExecuteTemplate(data interface{}) (string, error) {
+	buffer := bytes.Buffer{}
+	if err := t.template.Execute(&buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}